@@ -0,0 +1,42 @@
+// Package config implements the "rclone config" command tree.
+package config
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/rclone/rclone/cmd"
+	"github.com/rclone/rclone/fs/config"
+)
+
+// convertTo holds the value of convertCommand's --to flag.
+var convertTo string
+
+func init() {
+	convertCommand.Flags().StringVar(&convertTo, "to", "", "Config file type to convert to (json, yaml or conf)")
+	configCommand.AddCommand(convertCommand)
+	cmd.Root.AddCommand(configCommand)
+}
+
+var configCommand = &cobra.Command{
+	Use:   "config",
+	Short: `Enter an interactive configuration session.`,
+}
+
+var convertCommand = &cobra.Command{
+	Use:   "convert",
+	Short: `Convert the config file to a different file type.`,
+	Long: `Convert rewrites the config file at --config into the file type named
+by --to ("json", "yaml" or "conf"), round-tripping every remote through
+the Provider/RemoteConfig interfaces so it works between any two
+registered providers regardless of on-disk format. The original file is
+left untouched unless --to names its own file type, in which case it is
+rewritten in place; --config is updated to point at the new one.`,
+	RunE: func(command *cobra.Command, args []string) error {
+		cmd.CheckArgs(0, 0, command, args)
+		if convertTo == "" {
+			return errors.New("--to is required")
+		}
+		return config.ConvertConfig(convertTo)
+	},
+}
@@ -12,7 +12,6 @@ import (
 	"log"
 	mathrand "math/rand"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -56,7 +55,10 @@ const (
 
 // Global
 var (
-	// provider is the global config data provider. Don't read it directly, use getConfigData()
+	// provider is the global config data provider, set up by LoadConfig
+	// and swapped out by ConvertConfig/WatchConfig. Don't read it
+	// directly outside this package - use GetConfigProvider/
+	// GetRemoteConfig, which read the atomically-published copy.
 	provider Provider
 
 	// ConfigPath points to the config file
@@ -160,14 +162,17 @@ func init() {
 	}
 }
 
-// GetRemoteConfig returns the RemoteConfig interface of the current config provider
+// GetRemoteConfig returns the RemoteConfig interface of the current config
+// provider. It reads the atomically-published provider so it's safe to
+// call while WatchConfig is reloading in the background.
 func GetRemoteConfig() RemoteConfig {
-	return provider.GetRemoteConfig()
+	return currentProviderValue().GetRemoteConfig()
 }
 
-// GetConfigProvider returns the raw config provider
+// GetConfigProvider returns the raw config provider. Like
+// GetRemoteConfig, it reads the atomically-published provider.
 func GetConfigProvider() Provider {
-	return provider
+	return currentProviderValue()
 }
 
 // Save calling function which saves configuration file.
@@ -188,15 +193,18 @@ func SaveConfig() {
 
 // LoadConfig loads the config file
 func LoadConfig() {
-	for _, cp := range configProviders {
-		for _, ft := range cp.FileTypes {
-			if ft == path.Ext(ConfigPath)[1:] {
-				provider = cp.NewFunc()
-				break
-			}
-		}
+	providerMu.Lock()
+	defer providerMu.Unlock()
+
+	// Resolve --config-secret-store before reading the config file itself,
+	// since loadConfigFile needs the active SecretStore to fetch the
+	// config encryption key.
+	if err := InitSecretStore(); err != nil {
+		log.Fatalf("Failed to initialise --config-secret-store: %v", err)
 	}
 
+	provider = providerForPath(ConfigPath)
+
 	// Load configuration file.
 	var err error
 
@@ -209,6 +217,15 @@ func LoadConfig() {
 		fs.Debugf(nil, "Using config file from %q", ConfigPath)
 	}
 
+	// Merge in the optional base layer below, and the overlay/drop-in
+	// layers above, the provider we just loaded from ConfigPath.
+	layered, err := buildLayeredProvider(provider)
+	if err != nil {
+		log.Fatalf("Failed to build layered config: %v", err)
+	}
+	provider = layered
+	publishProvider(provider)
+
 	// Start the token bucket limiter
 	accounting.StartTokenBucket()
 
@@ -328,11 +345,18 @@ func loadConfigFile() error {
 	b, err := ioutil.ReadFile(ConfigPath)
 	if err != nil {
 		if os.IsNotExist(err) {
+			if provider == nil {
+				provider = sniffProvider(nil)
+			}
 			return errorConfigFileNotFound
 		}
 		return err
 	}
 
+	if provider == nil {
+		provider = sniffProvider(b)
+	}
+
 	// Find first non-empty line
 	r := bufio.NewReader(bytes.NewBuffer(b))
 	for {
@@ -367,6 +391,13 @@ func loadConfigFile() error {
 		return errors.New("Configuration data too short")
 	}
 
+	if len(configKey) == 0 {
+		if key, err := activeSecretStore.GetKey(configKeySecretName); err == nil {
+			configKey = key
+			fs.Debugf(nil, "using configKey from the configured secret store")
+		}
+	}
+
 	var out []byte
 	for {
 		if envKeyFile := os.Getenv("_RCLONE_CONFIG_KEY_FILE"); len(envKeyFile) > 0 {
@@ -434,7 +465,10 @@ func saveConfig() error {
 
 	var buf bytes.Buffer
 
-	err = provider.Save(&buf)
+	// Read the atomically-published provider rather than the raw
+	// package var: WatchConfig's reload runs on its own goroutine and
+	// only publishes a new provider once it's fully loaded.
+	err = currentProviderValue().Save(&buf)
 	if err != nil {
 		return errors.Errorf("Failed to save config file: %v", err)
 	}
@@ -469,6 +503,17 @@ func saveConfig() error {
 			return errors.Errorf("Failed to write temp config file: %v", err)
 		}
 		_ = enc.Close()
+
+		// configKey is only ever refreshed from the store when this
+		// process starts with none cached, so if an operator has rotated
+		// it out-of-band since then, the store now holds a key we didn't
+		// encrypt with. Check before writing rather than clobbering that
+		// rotation with our stale in-memory copy.
+		if existing, err := activeSecretStore.GetKey(configKeySecretName); err == nil && !bytes.Equal(existing, configKey) {
+			fs.Logf(nil, "configKey in the secret store has changed since this process loaded it - not overwriting it")
+		} else if err := activeSecretStore.PutKey(configKeySecretName, configKey); err != nil {
+			fs.Debugf(nil, "Not persisting configKey to the secret store: %v", err)
+		}
 	}
 
 	err = f.Close()
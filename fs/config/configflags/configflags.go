@@ -0,0 +1,17 @@
+// Package configflags defines the command line flags for locating and
+// layering the config file, shared by every rclone command via
+// cmd.Root's persistent flags.
+package configflags
+
+import (
+	"github.com/spf13/pflag"
+
+	"github.com/rclone/rclone/fs/config"
+)
+
+// AddFlags adds the config file flags to flagSet.
+func AddFlags(flagSet *pflag.FlagSet) {
+	flagSet.StringVarP(&config.ConfigPath, "config", "", config.ConfigPath, "Config file.")
+	flagSet.StringVarP(&config.ConfigOverlay, "config-overlay", "", "", "Config file whose remotes override --config, layered on top of it.")
+	flagSet.StringVarP(&config.ConfigSecretStoreURL, "config-secret-store", "", "", "URL of the SecretStore guarding the config encryption key, e.g. \"keyring://\" or \"vault://host/secret/data/rclone\".")
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withConvertFixture registers a "fake" provider type backed by
+// memProvider, points ConfigPath at a file under a temp directory and
+// publishes src as the current provider, restoring all of it afterwards.
+func withConvertFixture(t *testing.T, src Provider) {
+	t.Helper()
+
+	savedProviders := configProviders
+	configProviders = nil
+	t.Cleanup(func() { configProviders = savedProviders })
+	RegisterConfigProvider(&ProviderDefinition{NewFunc: newMemProvider, FileTypes: []string{"fake"}})
+
+	savedConfigPath := ConfigPath
+	savedBase := BaseConfigPath
+	savedOverlay := ConfigOverlay
+	t.Cleanup(func() {
+		ConfigPath = savedConfigPath
+		BaseConfigPath = savedBase
+		ConfigOverlay = savedOverlay
+	})
+	ConfigPath = filepath.Join(t.TempDir(), "rclone.conf")
+	BaseConfigPath = ""
+	ConfigOverlay = ""
+
+	publishProvider(src)
+}
+
+func TestConvertConfigRoundTrip(t *testing.T) {
+	src := newMemProvider()
+	srcRemotes := src.GetRemoteConfig()
+	section := srcRemotes.CreateRemote("myremote")
+	section.SetString("type", "local")
+	section.SetString("nested", "some value")
+
+	withConvertFixture(t, src)
+
+	err := ConvertConfig("fake")
+	require.NoError(t, err)
+
+	dst := currentProviderValue()
+	require.NotNil(t, dst)
+	dstSection := dst.GetRemoteConfig().GetRemote("myremote")
+	assert.Equal(t, "local", dstSection.GetString("type"))
+	assert.Equal(t, "some value", dstSection.GetString("nested"))
+
+	// The new path is derived from the old one and actually exists on disk.
+	assert.Equal(t, filepath.Join(filepath.Dir(ConfigPath), "rclone.fake"), ConfigPath)
+	_, err = os.Stat(ConfigPath)
+	assert.NoError(t, err)
+}
+
+func TestConvertConfigPreservesOverlayLayer(t *testing.T) {
+	src := newMemProvider()
+	src.GetRemoteConfig().CreateRemote("myremote").SetString("type", "local")
+
+	withConvertFixture(t, src)
+
+	overlayProvider := newMemProvider()
+	overlayProvider.GetRemoteConfig().CreateRemote("myremote").SetString("type", "s3")
+	var overlayBuf strings.Builder
+	require.NoError(t, overlayProvider.Save(&overlayBuf))
+	overlayPath := filepath.Join(t.TempDir(), "overlay.fake")
+	require.NoError(t, ioutil.WriteFile(overlayPath, []byte(overlayBuf.String()), 0600))
+	ConfigOverlay = overlayPath
+
+	err := ConvertConfig("fake")
+	require.NoError(t, err)
+
+	dst := currentProviderValue()
+	section := dst.GetRemoteConfig().GetRemote("myremote").(LayeredSection)
+	assert.Equal(t, "s3", section.GetString("type"))
+	assert.Equal(t, overlayPath, section.SourceOf("type"))
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// memProvider is a minimal in-memory Provider/RemoteConfig/Section for
+// exercising LayeredProvider and ConvertConfig without needing a real
+// goconfig/json/yaml provider. Save/Load round-trip through a trivial
+// "remote\tkey\tvalue" line format rather than being no-ops, so a
+// memProvider can stand in anywhere a test needs content to actually
+// survive a write-to-disk-and-read-back cycle.
+type memProvider struct {
+	remotes map[string]map[string]string
+}
+
+func newMemProvider() Provider {
+	return &memProvider{remotes: make(map[string]map[string]string)}
+}
+
+func (p *memProvider) String() string {
+	buf := &strings.Builder{}
+	_ = p.Save(buf)
+	return buf.String()
+}
+
+func (p *memProvider) Load(r io.Reader) error {
+	p.remotes = make(map[string]map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			return fmt.Errorf("malformed memProvider line %q", line)
+		}
+		if p.remotes[parts[0]] == nil {
+			p.remotes[parts[0]] = make(map[string]string)
+		}
+		p.remotes[parts[0]][parts[1]] = parts[2]
+	}
+	return scanner.Err()
+}
+
+func (p *memProvider) Save(w io.Writer) error {
+	for remote, values := range p.remotes {
+		for key, value := range values {
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", remote, key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *memProvider) GetRemoteConfig() RemoteConfig { return p }
+
+func (p *memProvider) ListRemotes() []string {
+	var names []string
+	for name := range p.remotes {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (p *memProvider) HasRemote(remote string) bool {
+	_, ok := p.remotes[remote]
+	return ok
+}
+
+func (p *memProvider) GetRemote(remote string) Section {
+	return &memSection{values: p.remotes[remote]}
+}
+
+func (p *memProvider) CreateRemote(remote string) Section {
+	if p.remotes[remote] == nil {
+		p.remotes[remote] = make(map[string]string)
+	}
+	return p.GetRemote(remote)
+}
+
+func (p *memProvider) DeleteRemote(name string) {
+	delete(p.remotes, name)
+}
+
+var (
+	_ Provider     = (*memProvider)(nil)
+	_ RemoteConfig = (*memProvider)(nil)
+)
+
+// memSection is the Section half of memProvider. A nil values map (the
+// remote doesn't exist in this particular layer) behaves as empty for
+// Keys, GetString and Delete; SetString on it panics, so the remote has
+// to be created first.
+type memSection struct {
+	values map[string]string
+}
+
+func (s *memSection) Keys() []string {
+	var keys []string
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (s *memSection) Delete(name string) bool {
+	if _, ok := s.values[name]; !ok {
+		return false
+	}
+	delete(s.values, name)
+	return true
+}
+
+func (s *memSection) GetString(name string) string {
+	return s.values[name]
+}
+
+func (s *memSection) SetString(name string, value string) {
+	s.values[name] = value
+}
+
+var _ Section = (*memSection)(nil)
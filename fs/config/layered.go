@@ -0,0 +1,277 @@
+package config
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/rclone/rclone/fs"
+)
+
+// Global
+var (
+	// BaseConfigPath points to an optional system-wide config file that is
+	// merged in below ConfigPath - settings here are overridden by
+	// anything in ConfigPath, --config-overlay or the drop-in directory.
+	BaseConfigPath = makeBaseConfigPath()
+
+	// ConfigOverlay points to an optional config file whose remotes
+	// override ConfigPath, set with --config-overlay. Empty means unused.
+	ConfigOverlay string
+)
+
+// configDropinDir is the directory scanned for "*.conf" drop-in files
+// that are layered in (each as its own layer, in filename order) above
+// ConfigPath and ConfigOverlay.
+func configDropinDir() string {
+	return ConfigPath + ".d"
+}
+
+func makeBaseConfigPath() string {
+	if runtime.GOOS == "windows" {
+		if programData := os.Getenv("PROGRAMDATA"); programData != "" {
+			return filepath.Join(programData, "rclone", configFileName)
+		}
+		return ""
+	}
+	return filepath.Join("/etc", "rclone", configFileName)
+}
+
+// Layer is one source in a LayeredProvider: an underlying Provider, the
+// path it was loaded from (for debugging and error messages) and whether
+// writes may be saved back to it.
+type Layer struct {
+	Provider Provider
+	Path     string
+	Writable bool
+}
+
+// LayeredProvider merges several Providers into one logical view. Layers
+// are given lowest-priority first; GetRemote/GetString shadow, with
+// later (higher-index) layers winning per key. SaveConfig only ever
+// writes to the topmost layer marked Writable.
+type LayeredProvider struct {
+	layers []Layer
+}
+
+// NewLayeredProvider returns a LayeredProvider over layers, ordered from
+// lowest to highest priority.
+func NewLayeredProvider(layers ...Layer) *LayeredProvider {
+	return &LayeredProvider{layers: layers}
+}
+
+// writableLayer returns the highest-priority layer marked Writable, or
+// nil if there isn't one.
+func (l *LayeredProvider) writableLayer() *Layer {
+	for i := len(l.layers) - 1; i >= 0; i-- {
+		if l.layers[i].Writable {
+			return &l.layers[i]
+		}
+	}
+	return nil
+}
+
+func (l *LayeredProvider) String() string {
+	buf := bytes.Buffer{}
+	if err := l.Save(&buf); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// Load loads r into the topmost writable layer, leaving the other layers
+// untouched. Building a LayeredProvider from several files on disk
+// should normally go through buildLayeredProvider instead of this.
+func (l *LayeredProvider) Load(r io.Reader) error {
+	w := l.writableLayer()
+	if w == nil {
+		return errors.New("no writable layer to load into")
+	}
+	return w.Provider.Load(r)
+}
+
+// Save writes the topmost writable layer to w.
+func (l *LayeredProvider) Save(w io.Writer) error {
+	writable := l.writableLayer()
+	if writable == nil {
+		return errors.New("no writable layer to save")
+	}
+	return writable.Provider.Save(w)
+}
+
+// GetRemoteConfig returns the RemoteConfig view of the layered provider.
+func (l *LayeredProvider) GetRemoteConfig() RemoteConfig {
+	return l
+}
+
+// ListRemotes returns the union of remotes across all layers.
+func (l *LayeredProvider) ListRemotes() []string {
+	seen := make(map[string]bool)
+	var remotes []string
+	for _, layer := range l.layers {
+		for _, remote := range layer.Provider.GetRemoteConfig().ListRemotes() {
+			if !seen[remote] {
+				seen[remote] = true
+				remotes = append(remotes, remote)
+			}
+		}
+	}
+	sort.Strings(remotes)
+	return remotes
+}
+
+// HasRemote returns true if remote is defined in any layer.
+func (l *LayeredProvider) HasRemote(remote string) bool {
+	for _, layer := range l.layers {
+		if layer.Provider.GetRemoteConfig().HasRemote(remote) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRemote returns a Section that shadows the named remote across all
+// layers, later layers taking priority per key.
+func (l *LayeredProvider) GetRemote(remote string) Section {
+	return newLayeredSection(l, remote)
+}
+
+// CreateRemote creates remote in the topmost writable layer.
+func (l *LayeredProvider) CreateRemote(remote string) Section {
+	w := l.writableLayer()
+	if w == nil {
+		fs.Errorf(nil, "No writable config layer - can't create remote %q", remote)
+		return l.GetRemote(remote)
+	}
+	w.Provider.GetRemoteConfig().CreateRemote(remote)
+	return l.GetRemote(remote)
+}
+
+// DeleteRemote deletes remote from the topmost writable layer. It does
+// not remove any definition of remote baked into a lower, read-only
+// layer - that will still shadow through once the writable copy is gone.
+func (l *LayeredProvider) DeleteRemote(name string) {
+	w := l.writableLayer()
+	if w == nil {
+		return
+	}
+	w.Provider.GetRemoteConfig().DeleteRemote(name)
+}
+
+var (
+	_ Provider     = (*LayeredProvider)(nil)
+	_ RemoteConfig = (*LayeredProvider)(nil)
+)
+
+// loadPlainProviderFile loads path (which must not be encrypted) into a
+// fresh Provider chosen by its extension, falling back to sniffing its
+// content. It returns nil, nil if path doesn't exist.
+func loadPlainProviderFile(path string) (Provider, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	p := providerForPath(path)
+	if p == nil {
+		p = sniffProvider(b)
+	}
+	if p == nil {
+		return nil, errors.Errorf("no config provider registered to read %q", path)
+	}
+	if err := p.Load(bytes.NewReader(b)); err != nil {
+		return nil, errors.Wrapf(err, "failed to load %q", path)
+	}
+	return p, nil
+}
+
+// loadDropinLayers loads every "*.conf"-style file (matching the
+// registered provider extensions) in dir, in filename order, each as
+// its own Layer.
+func loadDropinLayers(dir string) ([]Layer, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if ext == "" || providerDefinitionForFileType(ext[1:]) == nil {
+			// Not one of our registered file types - an editor swap
+			// file, a ".bak", a stray README - ignore it rather than
+			// letting loadPlainProviderFile sniff it as INI.
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	var layers []Layer
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		p, err := loadPlainProviderFile(path)
+		if err != nil {
+			fs.Errorf(nil, "Skipping config drop-in %q: %v", path, err)
+			continue
+		}
+		if p == nil {
+			continue
+		}
+		layers = append(layers, Layer{Provider: p, Path: path, Writable: false})
+	}
+	return layers, nil
+}
+
+// buildLayeredProvider combines BaseConfigPath, userProvider (already
+// loaded from ConfigPath by loadConfigFile), ConfigOverlay and the
+// rclone.conf.d drop-in directory into a single LayeredProvider. It
+// returns userProvider unchanged if none of the extra sources are
+// present, so the common case stays a plain Provider.
+func buildLayeredProvider(userProvider Provider) (Provider, error) {
+	var layers []Layer
+
+	if BaseConfigPath != "" {
+		base, err := loadPlainProviderFile(BaseConfigPath)
+		if err != nil {
+			fs.Errorf(nil, "Failed to load base config %q: %v", BaseConfigPath, err)
+		} else if base != nil {
+			layers = append(layers, Layer{Provider: base, Path: BaseConfigPath, Writable: false})
+		}
+	}
+
+	layers = append(layers, Layer{Provider: userProvider, Path: ConfigPath, Writable: true})
+
+	if ConfigOverlay != "" {
+		overlay, err := loadPlainProviderFile(ConfigOverlay)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load --config-overlay")
+		}
+		if overlay != nil {
+			layers = append(layers, Layer{Provider: overlay, Path: ConfigOverlay, Writable: false})
+		}
+	}
+
+	dropins, err := loadDropinLayers(configDropinDir())
+	if err != nil {
+		fs.Errorf(nil, "Failed to scan config drop-in directory %q: %v", configDropinDir(), err)
+	}
+	layers = append(layers, dropins...)
+
+	if len(layers) == 1 {
+		return userProvider, nil
+	}
+	return NewLayeredProvider(layers...), nil
+}
@@ -0,0 +1,108 @@
+package config
+
+// layeredSection shadows a remote across all layers of a LayeredProvider:
+// GetString/Keys walk from the highest-priority layer down and return
+// the first layer that has the remote at all, falling back to lower
+// layers for keys missing there. Writes always go to the topmost
+// writable layer.
+type layeredSection struct {
+	provider *LayeredProvider
+	remote   string
+}
+
+func newLayeredSection(provider *LayeredProvider, remote string) Section {
+	return &layeredSection{provider: provider, remote: remote}
+}
+
+// sections returns the Section for remote in every layer that has it,
+// highest priority first.
+func (s *layeredSection) sections() []layeredValue {
+	var sections []layeredValue
+	for i := len(s.provider.layers) - 1; i >= 0; i-- {
+		layer := s.provider.layers[i]
+		if layer.Provider.GetRemoteConfig().HasRemote(s.remote) {
+			sections = append(sections, layeredValue{
+				path:    layer.Path,
+				section: layer.Provider.GetRemoteConfig().GetRemote(s.remote),
+			})
+		}
+	}
+	return sections
+}
+
+type layeredValue struct {
+	path    string
+	section Section
+}
+
+func (s *layeredSection) Keys() []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, v := range s.sections() {
+		for _, k := range v.section.Keys() {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	return keys
+}
+
+func (s *layeredSection) Delete(name string) bool {
+	w := s.provider.writableLayer()
+	if w == nil {
+		return false
+	}
+	return w.Provider.GetRemoteConfig().GetRemote(s.remote).Delete(name)
+}
+
+func (s *layeredSection) GetString(name string) string {
+	for _, v := range s.sections() {
+		if contains(v.section.Keys(), name) {
+			return v.section.GetString(name)
+		}
+	}
+	return ""
+}
+
+func (s *layeredSection) SetString(name string, value string) {
+	w := s.provider.writableLayer()
+	if w == nil {
+		return
+	}
+	w.Provider.GetRemoteConfig().GetRemote(s.remote).SetString(name, value)
+}
+
+// SourceOf returns the path of the layer that name would be read from,
+// or "" if it isn't set in any layer. Useful for debugging which of
+// several overlapping config files is in effect for a given option.
+func (s *layeredSection) SourceOf(name string) string {
+	for _, v := range s.sections() {
+		if contains(v.section.Keys(), name) {
+			return v.path
+		}
+	}
+	return ""
+}
+
+func contains(haystack []string, needle string) bool {
+	for _, v := range haystack {
+		if v == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// LayeredSection is implemented by the Section returned from a
+// LayeredProvider's GetRemote, exposing which layer a value came from.
+type LayeredSection interface {
+	Section
+	SourceOf(name string) string
+}
+
+var (
+	_ Section        = (*layeredSection)(nil)
+	_ LayeredSection = (*layeredSection)(nil)
+)
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLayer(path string, writable bool, remotes map[string]map[string]string) Layer {
+	p := newMemProvider().(*memProvider)
+	for name, values := range remotes {
+		p.remotes[name] = values
+	}
+	return Layer{Provider: p, Path: path, Writable: writable}
+}
+
+func TestLayeredSectionPrecedence(t *testing.T) {
+	base := newTestLayer("base.conf", false, map[string]map[string]string{
+		"myremote": {"type": "local", "base-only": "base"},
+	})
+	overlay := newTestLayer("overlay.conf", false, map[string]map[string]string{
+		"myremote": {"type": "s3", "overlay-only": "overlay"},
+	})
+	user := newTestLayer("user.conf", true, map[string]map[string]string{
+		"myremote": {},
+	})
+	lp := NewLayeredProvider(base, overlay, user)
+	section := lp.GetRemote("myremote").(LayeredSection)
+
+	for _, test := range []struct {
+		key        string
+		wantValue  string
+		wantSource string
+	}{
+		// Higher-priority overlay shadows base for a key both define.
+		{"type", "s3", "overlay.conf"},
+		// Falls through to base for a key only base defines.
+		{"base-only", "base", "base.conf"},
+		// Falls through to overlay for a key only overlay defines.
+		{"overlay-only", "overlay", "overlay.conf"},
+		// Unset anywhere.
+		{"missing", "", ""},
+	} {
+		assert.Equal(t, test.wantValue, section.GetString(test.key), "GetString(%q)", test.key)
+		assert.Equal(t, test.wantSource, section.SourceOf(test.key), "SourceOf(%q)", test.key)
+	}
+}
+
+func TestLayeredSectionWritesGoToWritableLayer(t *testing.T) {
+	base := newTestLayer("base.conf", false, map[string]map[string]string{
+		"myremote": {"type": "local"},
+	})
+	user := newTestLayer("user.conf", true, nil)
+	lp := NewLayeredProvider(base, user)
+
+	created := lp.CreateRemote("myremote")
+	created.SetString("type", "s3")
+
+	section := lp.GetRemote("myremote").(LayeredSection)
+	assert.Equal(t, "s3", section.GetString("type"))
+	assert.Equal(t, "user.conf", section.SourceOf("type"))
+
+	// The read-only base layer underneath is untouched.
+	assert.Equal(t, "local", base.Provider.GetRemoteConfig().GetRemote("myremote").GetString("type"))
+}
+
+func TestLayeredProviderListAndHasRemote(t *testing.T) {
+	base := newTestLayer("base.conf", false, map[string]map[string]string{
+		"a": {"type": "local"},
+	})
+	user := newTestLayer("user.conf", true, map[string]map[string]string{
+		"b": {"type": "s3"},
+	})
+	lp := NewLayeredProvider(base, user)
+
+	assert.ElementsMatch(t, []string{"a", "b"}, lp.ListRemotes())
+	assert.True(t, lp.HasRemote("a"))
+	assert.True(t, lp.HasRemote("b"))
+	assert.False(t, lp.HasRemote("c"))
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"log"
+	"strings"
 
 	"github.com/Unknwon/goconfig"
 
@@ -17,8 +18,18 @@ func Register() {
 	})
 }
 
+// NewGoConfigProvider returns a GoConfig pre-seeded with an empty
+// *goconfig.ConfigFile, the same way NewJSONProvider/NewYAMLProvider
+// pre-initialize their maps - callers like ConvertConfig create a
+// Provider and write remotes straight into it without ever calling
+// Load, and GoConfig.CreateRemote would otherwise panic on a nil
+// g.config.
 func NewGoConfigProvider() config.Provider {
-	return &GoConfig{}
+	c, err := goconfig.LoadFromReader(strings.NewReader(""))
+	if err != nil {
+		log.Fatalf("failed to initialise empty config: %v", err)
+	}
+	return &GoConfig{config: c}
 }
 
 type GoConfig struct {
@@ -0,0 +1,60 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+
+	"github.com/rclone/rclone/fs/config"
+)
+
+func Register() {
+	config.RegisterConfigProvider(&config.ProviderDefinition{
+		NewFunc:   NewJSONProvider,
+		FileTypes: []string{"json"},
+	})
+}
+
+func NewJSONProvider() config.Provider {
+	return &JSONConfig{
+		data: make(map[string]map[string]string),
+	}
+}
+
+type JSONConfig struct {
+	data map[string]map[string]string
+}
+
+func (j *JSONConfig) String() string {
+	buf := bytes.Buffer{}
+	err := j.Save(&buf)
+	if err != nil {
+		log.Fatalf("error stringifying config: %v", err)
+		return ""
+	}
+	return buf.String()
+}
+
+func (j *JSONConfig) Load(r io.Reader) error {
+	data := make(map[string]map[string]string)
+	if err := json.NewDecoder(r).Decode(&data); err != nil && err != io.EOF {
+		return err
+	}
+	j.data = data
+	return nil
+}
+
+func (j *JSONConfig) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(j.data)
+}
+
+func (j *JSONConfig) GetRemoteConfig() config.RemoteConfig {
+	return j
+}
+
+var (
+	_ config.Provider = (*JSONConfig)(nil)
+)
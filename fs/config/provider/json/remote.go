@@ -0,0 +1,53 @@
+package json
+
+import (
+	"sort"
+
+	"github.com/rclone/rclone/fs/config"
+)
+
+func (j *JSONConfig) ListRemotes() []string {
+	remotes := make([]string, 0, len(j.data))
+	for remote := range j.data {
+		remotes = append(remotes, remote)
+	}
+	sort.Strings(remotes)
+	return remotes
+}
+
+func (j *JSONConfig) HasRemote(remote string) bool {
+	_, ok := j.data[remote]
+	return ok
+}
+
+func (j *JSONConfig) GetRemote(remote string) config.Section {
+	return newSection(j, remote)
+}
+
+func (j *JSONConfig) CreateRemote(remote string) config.Section {
+	if j.data[remote] == nil {
+		j.data[remote] = make(map[string]string)
+	}
+	return j.GetRemote(remote)
+}
+
+func (j *JSONConfig) DeleteRemote(name string) {
+	delete(j.data, name)
+}
+
+func (j *JSONConfig) RenameRemote(oldName string, newName string) {
+	j.CopyRemote(oldName, newName)
+	delete(j.data, oldName)
+}
+
+func (j *JSONConfig) CopyRemote(source string, destination string) {
+	dest := make(map[string]string, len(j.data[source]))
+	for k, v := range j.data[source] {
+		dest[k] = v
+	}
+	j.data[destination] = dest
+}
+
+var (
+	_ config.RemoteConfig = (*JSONConfig)(nil)
+)
@@ -0,0 +1,71 @@
+package yaml
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"log"
+
+	yamlv2 "gopkg.in/yaml.v2"
+
+	"github.com/rclone/rclone/fs/config"
+)
+
+func Register() {
+	config.RegisterConfigProvider(&config.ProviderDefinition{
+		NewFunc:   NewYAMLProvider,
+		FileTypes: []string{"yaml", "yml"},
+	})
+}
+
+func NewYAMLProvider() config.Provider {
+	return &YAMLConfig{
+		data: make(map[string]map[string]string),
+	}
+}
+
+type YAMLConfig struct {
+	data map[string]map[string]string
+}
+
+func (y *YAMLConfig) String() string {
+	buf := bytes.Buffer{}
+	err := y.Save(&buf)
+	if err != nil {
+		log.Fatalf("error stringifying config: %v", err)
+		return ""
+	}
+	return buf.String()
+}
+
+func (y *YAMLConfig) Load(r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	data := make(map[string]map[string]string)
+	if len(b) > 0 {
+		if err := yamlv2.Unmarshal(b, &data); err != nil {
+			return err
+		}
+	}
+	y.data = data
+	return nil
+}
+
+func (y *YAMLConfig) Save(w io.Writer) error {
+	b, err := yamlv2.Marshal(y.data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (y *YAMLConfig) GetRemoteConfig() config.RemoteConfig {
+	return y
+}
+
+var (
+	_ config.Provider = (*YAMLConfig)(nil)
+)
@@ -0,0 +1,53 @@
+package yaml
+
+import (
+	"sort"
+
+	"github.com/rclone/rclone/fs/config"
+)
+
+func (y *YAMLConfig) ListRemotes() []string {
+	remotes := make([]string, 0, len(y.data))
+	for remote := range y.data {
+		remotes = append(remotes, remote)
+	}
+	sort.Strings(remotes)
+	return remotes
+}
+
+func (y *YAMLConfig) HasRemote(remote string) bool {
+	_, ok := y.data[remote]
+	return ok
+}
+
+func (y *YAMLConfig) GetRemote(remote string) config.Section {
+	return newSection(y, remote)
+}
+
+func (y *YAMLConfig) CreateRemote(remote string) config.Section {
+	if y.data[remote] == nil {
+		y.data[remote] = make(map[string]string)
+	}
+	return y.GetRemote(remote)
+}
+
+func (y *YAMLConfig) DeleteRemote(name string) {
+	delete(y.data, name)
+}
+
+func (y *YAMLConfig) RenameRemote(oldName string, newName string) {
+	y.CopyRemote(oldName, newName)
+	delete(y.data, oldName)
+}
+
+func (y *YAMLConfig) CopyRemote(source string, destination string) {
+	dest := make(map[string]string, len(y.data[source]))
+	for k, v := range y.data[source] {
+		dest[k] = v
+	}
+	y.data[destination] = dest
+}
+
+var (
+	_ config.RemoteConfig = (*YAMLConfig)(nil)
+)
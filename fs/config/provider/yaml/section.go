@@ -0,0 +1,51 @@
+package yaml
+
+import (
+	"sort"
+
+	"github.com/rclone/rclone/fs/config"
+)
+
+type section struct {
+	config *YAMLConfig
+	remote string
+}
+
+func (s *section) Keys() []string {
+	keys := make([]string, 0, len(s.config.data[s.remote]))
+	for k := range s.config.data[s.remote] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *section) Delete(name string) bool {
+	if _, ok := s.config.data[s.remote][name]; !ok {
+		return false
+	}
+	delete(s.config.data[s.remote], name)
+	return true
+}
+
+func (s *section) GetString(name string) string {
+	return s.config.data[s.remote][name]
+}
+
+func (s *section) SetString(name string, value string) {
+	if s.config.data[s.remote] == nil {
+		s.config.data[s.remote] = make(map[string]string)
+	}
+	s.config.data[s.remote][name] = value
+}
+
+func newSection(config *YAMLConfig, remote string) config.Section {
+	return &section{
+		config: config,
+		remote: remote,
+	}
+}
+
+var (
+	_ config.Section = (*section)(nil)
+)
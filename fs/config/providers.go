@@ -0,0 +1,92 @@
+package config
+
+import (
+	"bytes"
+	"path"
+	"strings"
+)
+
+// defaultFileType is the file type used when the config path has no
+// or unrecognised extension and the content can't be sniffed, e.g. a
+// --config path such as "rclone" with no suffix at all.
+const defaultFileType = "conf"
+
+// providerDefinitionForFileType returns the registered ProviderDefinition
+// for the given file type (without the leading dot), or nil if none is
+// registered.
+func providerDefinitionForFileType(fileType string) *ProviderDefinition {
+	for _, cp := range configProviders {
+		for _, ft := range cp.FileTypes {
+			if ft == fileType {
+				return cp
+			}
+		}
+	}
+	return nil
+}
+
+// providerForPath returns a new Provider appropriate for the extension of
+// configPath, or nil if the extension is missing or unrecognised - in
+// which case the caller should fall back to sniffProvider once the file
+// content is available.
+func providerForPath(configPath string) Provider {
+	ext := path.Ext(configPath)
+	if ext == "" {
+		return nil
+	}
+	cp := providerDefinitionForFileType(ext[1:])
+	if cp == nil {
+		return nil
+	}
+	return cp.NewFunc()
+}
+
+// skipCommentLines returns data starting from its first blank-trimmed
+// line that isn't empty or an INI/rclone.conf comment (";" or "#",
+// matching loadConfigFile's own comment handling), so a leading comment
+// that happens to contain a ":" or start with "{" can't fool
+// sniffProvider into picking the wrong heuristic.
+func skipCommentLines(data []byte) []byte {
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		l := bytes.TrimSpace(line)
+		if len(l) == 0 || bytes.HasPrefix(l, []byte(";")) || bytes.HasPrefix(l, []byte("#")) {
+			continue
+		}
+		return bytes.Join(lines[i:], []byte("\n"))
+	}
+	return nil
+}
+
+// sniffProvider guesses the Provider to use from the content of the config
+// file, for when the path has no extension or an unrecognised one. It
+// falls back to defaultFileType to preserve the historic behaviour of
+// treating extensionless config files as INI.
+func sniffProvider(data []byte) Provider {
+	trimmed := bytes.TrimSpace(skipCommentLines(data))
+	fileType := defaultFileType
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		fileType = "json"
+	case len(trimmed) > 0 && !strings.HasPrefix(string(trimmed), "["):
+		// Neither JSON nor INI section headers - assume YAML, which has
+		// no other reliable marker at the start of the document.
+		if cp := providerDefinitionForFileType("yaml"); cp != nil {
+			firstLine := string(trimmed)
+			if i := bytes.IndexByte(trimmed, '\n'); i >= 0 {
+				firstLine = string(trimmed[:i])
+			}
+			if strings.Contains(firstLine, ":") {
+				fileType = "yaml"
+			}
+		}
+	}
+	cp := providerDefinitionForFileType(fileType)
+	if cp == nil {
+		cp = providerDefinitionForFileType(defaultFileType)
+	}
+	if cp == nil {
+		return nil
+	}
+	return cp.NewFunc()
+}
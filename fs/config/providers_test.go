@@ -0,0 +1,78 @@
+package config
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sniffMarkerProvider is a Provider that does nothing but remember which
+// registered file type produced it, so tests can assert on sniffProvider's
+// choice without needing a real goconfig/json/yaml provider.
+type sniffMarkerProvider struct {
+	fileType string
+}
+
+func (p *sniffMarkerProvider) String() string                { return "" }
+func (p *sniffMarkerProvider) Load(io.Reader) error          { return nil }
+func (p *sniffMarkerProvider) Save(io.Writer) error          { return nil }
+func (p *sniffMarkerProvider) GetRemoteConfig() RemoteConfig { return nil }
+
+var _ Provider = (*sniffMarkerProvider)(nil)
+
+// withSniffMarkerProviders registers fake "json", "yaml" and "conf"
+// providers for the duration of a test, restoring configProviders
+// afterwards.
+func withSniffMarkerProviders(t *testing.T) {
+	t.Helper()
+	saved := configProviders
+	configProviders = nil
+	t.Cleanup(func() { configProviders = saved })
+	for _, fileType := range []string{"json", "yaml", "conf"} {
+		fileType := fileType
+		RegisterConfigProvider(&ProviderDefinition{
+			NewFunc:   func() Provider { return &sniffMarkerProvider{fileType: fileType} },
+			FileTypes: []string{fileType},
+		})
+	}
+}
+
+func TestSniffProvider(t *testing.T) {
+	withSniffMarkerProviders(t)
+
+	for _, test := range []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "", "conf"},
+		{"ini section header", "[myremote]\ntype = local\n", "conf"},
+		{"json object", `{"myremote": {"type": "local"}}`, "json"},
+		{"yaml mapping", "myremote:\n  type: local\n", "yaml"},
+		{
+			"ini with leading semicolon comment",
+			"; Managed by: ansible\n[myremote]\ntype = local\n",
+			"conf",
+		},
+		{
+			"ini with leading hash comment containing a colon",
+			"# Managed by: ansible\n[myremote]\ntype = local\n",
+			"conf",
+		},
+		{
+			"ini with several leading blank and comment lines",
+			"\n\n# first\n; second\n\n[myremote]\ntype = local\n",
+			"conf",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			p := sniffProvider([]byte(test.data))
+			require.NotNil(t, p)
+			marker, ok := p.(*sniffMarkerProvider)
+			require.True(t, ok)
+			assert.Equal(t, test.want, marker.fileType)
+		})
+	}
+}
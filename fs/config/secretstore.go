@@ -0,0 +1,133 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/rclone/rclone/fs/config/obscure"
+)
+
+// configKeySecretName is the name the config encryption key is stored
+// under in whichever SecretStore is active.
+const configKeySecretName = "config"
+
+// secretRefPrefix marks a Section value as a reference into the active
+// SecretStore rather than an obscured literal, e.g. "keyring://my-token".
+const secretRefPrefix = "keyring://"
+
+// SecretStore abstracts where the config encryption key - and,
+// optionally, individual per-remote secrets - are kept, so they don't
+// have to live in RCLONE_CONFIG_PASS or be obscured inside the config
+// file itself.
+type SecretStore interface {
+	// GetKey returns the secret stored under name, or an error if it
+	// isn't present.
+	GetKey(name string) ([]byte, error)
+	// PutKey stores data under name, creating or overwriting it.
+	PutKey(name string, data []byte) error
+}
+
+// SecretStoreDefinition registers a SecretStore backend under the scheme
+// of its --config-secret-store URL, e.g. "keyring://" or
+// "vault://vault.example.com/secret/data/rclone".
+type SecretStoreDefinition struct {
+	Scheme  string
+	NewFunc func(url string) (SecretStore, error)
+}
+
+var secretStores []*SecretStoreDefinition
+
+// RegisterSecretStore makes a SecretStore backend available to
+// --config-secret-store.
+func RegisterSecretStore(sd *SecretStoreDefinition) {
+	secretStores = append(secretStores, sd)
+}
+
+// Global
+var (
+	// ConfigSecretStoreURL selects the SecretStore used for the config
+	// encryption key and any keyring:// secret references, e.g.
+	// "keyring://" or "vault://vault.example.com/secret/data/rclone". Empty
+	// keeps the historic RCLONE_CONFIG_PASS/prompt flow.
+	ConfigSecretStoreURL string
+
+	activeSecretStore SecretStore = envSecretStore{}
+)
+
+// InitSecretStore resolves ConfigSecretStoreURL into the SecretStore
+// used for the rest of the run. LoadConfig calls it directly, so callers
+// only need this themselves if they use loadConfigFile/saveConfig
+// without going through LoadConfig.
+func InitSecretStore() error {
+	if ConfigSecretStoreURL == "" {
+		activeSecretStore = envSecretStore{}
+		return nil
+	}
+	scheme := ConfigSecretStoreURL
+	if i := strings.Index(scheme, "://"); i >= 0 {
+		scheme = scheme[:i]
+	}
+	for _, sd := range secretStores {
+		if sd.Scheme == scheme {
+			store, err := sd.NewFunc(ConfigSecretStoreURL)
+			if err != nil {
+				return errors.Wrapf(err, "failed to initialise %s secret store", scheme)
+			}
+			activeSecretStore = store
+			return nil
+		}
+	}
+	return errors.Errorf("unknown --config-secret-store scheme %q", scheme)
+}
+
+// envSecretStore is the historic behaviour: the config key comes from
+// RCLONE_CONFIG_PASS, _RCLONE_CONFIG_KEY_FILE or an interactive prompt,
+// handled directly in loadConfigFile/saveConfig. It can't store
+// arbitrary named secrets, so keyring:// references aren't usable unless
+// --config-secret-store picks a real backend.
+type envSecretStore struct{}
+
+func (envSecretStore) GetKey(name string) ([]byte, error) {
+	return nil, errors.Errorf("%q is not available from the default secret store - set RCLONE_CONFIG_PASS or --config-secret-store", name)
+}
+
+func (envSecretStore) PutKey(name string, data []byte) error {
+	return errors.New("the default secret store can't persist secrets - set --config-secret-store")
+}
+
+var (
+	_ SecretStore = envSecretStore{}
+)
+
+// ResolveSecretValue turns the raw string stored against a config key
+// into its plaintext value. A "keyring://name" value is looked up in the
+// active SecretStore; anything else is treated as obscure.Obscure
+// output and revealed. Section implementations' GetSecret should call
+// this rather than calling obscure.Reveal directly, so a value can move
+// between the two representations transparently.
+func ResolveSecretValue(v string) (string, error) {
+	if v == "" {
+		return "", nil
+	}
+	if strings.HasPrefix(v, secretRefPrefix) {
+		name := strings.TrimPrefix(v, secretRefPrefix)
+		data, err := activeSecretStore.GetKey(name)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to resolve secret reference %q", v)
+		}
+		return string(data), nil
+	}
+	return obscure.Reveal(v)
+}
+
+// SetSecretRef stores value under refName in the active SecretStore and
+// points s's name option at it via a "keyring://refName" reference,
+// instead of obscuring value inline in the config file.
+func SetSecretRef(s Section, name, refName, value string) error {
+	if err := activeSecretStore.PutKey(refName, []byte(value)); err != nil {
+		return errors.Wrapf(err, "failed to store %q in the secret store", refName)
+	}
+	s.SetString(name, secretRefPrefix+refName)
+	return nil
+}
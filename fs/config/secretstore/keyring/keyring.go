@@ -0,0 +1,51 @@
+// Package keyring implements a config.SecretStore backed by the OS
+// credential manager (Keychain on macOS, Secret Service on Linux,
+// Credential Manager on Windows) via github.com/zalando/go-keyring.
+package keyring
+
+import (
+	"github.com/zalando/go-keyring"
+
+	"github.com/rclone/rclone/fs/config"
+)
+
+// serviceName is the keyring "service" every rclone secret is filed
+// under; name (the SecretStore key) becomes the keyring "user".
+const serviceName = "rclone"
+
+// Register makes the OS keyring available as --config-secret-store
+// keyring://.
+func Register() {
+	config.RegisterSecretStore(&config.SecretStoreDefinition{
+		Scheme:  "keyring",
+		NewFunc: New,
+	})
+}
+
+// New returns a SecretStore backed by the OS keyring. url is only used
+// to select this backend via its scheme - the OS keyring needs no
+// further addressing.
+func New(url string) (config.SecretStore, error) {
+	return Store{}, nil
+}
+
+// Store is a config.SecretStore backed by the OS keyring.
+type Store struct{}
+
+// GetKey implements config.SecretStore.
+func (Store) GetKey(name string) ([]byte, error) {
+	v, err := keyring.Get(serviceName, name)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(v), nil
+}
+
+// PutKey implements config.SecretStore.
+func (Store) PutKey(name string, data []byte) error {
+	return keyring.Set(serviceName, name, string(data))
+}
+
+var (
+	_ config.SecretStore = Store{}
+)
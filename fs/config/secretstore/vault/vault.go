@@ -0,0 +1,160 @@
+// Package vault implements a config.SecretStore backed by a HashiCorp
+// Vault KV version 2 secrets engine, addressed by a "vault://" URL such
+// as "vault://vault.example.com/secret/data/rclone". All names handled
+// through GetKey/PutKey are stored as fields of that one secret. The
+// Vault token is read from the VAULT_TOKEN environment variable.
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/rclone/rclone/fs/config"
+)
+
+// Register makes Vault available as --config-secret-store vault://
+// (HTTPS) and vault+http:// (plain HTTP).
+func Register() {
+	for _, scheme := range []string{"vault", "vault+http"} {
+		config.RegisterSecretStore(&config.SecretStoreDefinition{
+			Scheme:  scheme,
+			NewFunc: New,
+		})
+	}
+}
+
+// Store is a config.SecretStore backed by a Vault KV v2 secret.
+type Store struct {
+	addr   string
+	path   string
+	token  string
+	client *http.Client
+}
+
+// New parses rawURL (including its "vault://" or "vault+http://"
+// scheme) into a Store that talks to the Vault HTTP API.
+func New(rawURL string) (config.SecretStore, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid --config-secret-store vault URL")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		return nil, errors.New("VAULT_TOKEN must be set to use the vault secret store")
+	}
+	scheme := "https"
+	if u.Scheme == "vault+http" {
+		scheme = "http"
+	}
+	return &Store{
+		addr:   scheme + "://" + u.Host,
+		path:   strings.Trim(u.Path, "/"),
+		token:  token,
+		client: &http.Client{},
+	}, nil
+}
+
+func (s *Store) secretURL() string {
+	return fmt.Sprintf("%s/v1/%s", s.addr, s.path)
+}
+
+type kvV2Response struct {
+	Data struct {
+		Data map[string]string `json:"data"`
+	} `json:"data"`
+}
+
+// errSecretNotFound is returned by readAll when Vault has no secret at
+// all at s.path yet - the only case in which starting from an empty set
+// of fields on a PutKey is safe. Any other error (network failure, auth
+// failure, a 5xx, a malformed body) must not be treated as "empty",
+// since PutKey would then overwrite the whole KV v2 secret and destroy
+// every other field already stored there.
+var errSecretNotFound = errors.New("vault secret not found")
+
+func (s *Store) readAll() (map[string]string, error) {
+	req, err := http.NewRequest("GET", s.secretURL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "vault request failed")
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errSecretNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("vault returned status %s", resp.Status)
+	}
+	var kv kvV2Response
+	if err := json.NewDecoder(resp.Body).Decode(&kv); err != nil {
+		return nil, errors.Wrap(err, "failed to decode vault response")
+	}
+	return kv.Data.Data, nil
+}
+
+// GetKey implements config.SecretStore.
+func (s *Store) GetKey(name string) ([]byte, error) {
+	data, err := s.readAll()
+	if err == errSecretNotFound {
+		return nil, errors.Errorf("%q not found in vault secret %q", name, s.path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	v, ok := data[name]
+	if !ok {
+		return nil, errors.Errorf("%q not found in vault secret %q", name, s.path)
+	}
+	return []byte(v), nil
+}
+
+// PutKey implements config.SecretStore. It reads the secret, merges in
+// name, and writes the whole thing back - Vault KV v2 always replaces
+// the full secret on write. Only a genuine "no secret here yet" (404)
+// is treated as an empty starting point; any other read error aborts
+// the write instead of risking clobbering fields we failed to read.
+func (s *Store) PutKey(name string, data []byte) error {
+	fields, err := s.readAll()
+	if err == errSecretNotFound {
+		fields = make(map[string]string)
+	} else if err != nil {
+		return errors.Wrap(err, "failed to read existing vault secret before write")
+	}
+	fields[name] = string(data)
+
+	body, err := json.Marshal(map[string]interface{}{"data": fields})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", s.secretURL(), strings.NewReader(string(body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "vault request failed")
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		b, _ := ioutil.ReadAll(resp.Body)
+		return errors.Errorf("vault returned status %s: %s", resp.Status, b)
+	}
+	return nil
+}
+
+var (
+	_ config.SecretStore = (*Store)(nil)
+)
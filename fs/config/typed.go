@@ -0,0 +1,199 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/config/obscure"
+)
+
+// TypedSupporter is an optional interface a Section can implement to
+// store and retrieve non-string values natively, e.g. a JSON or YAML
+// provider that can keep an int as a number rather than round-tripping
+// it through a string.
+//
+// Providers that don't implement it still work: GetInt, GetBool and the
+// rest of the typed accessors below fall back to parsing the result of
+// GetString/SetString.
+type TypedSupporter interface {
+	GetInt(name string) int
+	SetInt(name string, value int)
+	GetInt64(name string) int64
+	SetInt64(name string, value int64)
+	GetBool(name string) bool
+	SetBool(name string, value bool)
+	GetDuration(name string) time.Duration
+	SetDuration(name string, value time.Duration)
+	GetSizeSuffix(name string) fs.SizeSuffix
+	SetSizeSuffix(name string, value fs.SizeSuffix)
+	GetStringList(name string) []string
+	SetStringList(name string, value []string)
+	GetSecret(name string) (string, error)
+	SetSecret(name string, value string) error
+}
+
+// GetInt returns the value of name in s as an int, using s's
+// TypedSupporter if it implements one, or parsing GetString otherwise.
+// It returns 0 if the value is unset or unparseable.
+func GetInt(s Section, name string) int {
+	if t, ok := s.(TypedSupporter); ok {
+		return t.GetInt(name)
+	}
+	v, err := strconv.Atoi(s.GetString(name))
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// SetInt sets name in s to value.
+func SetInt(s Section, name string, value int) {
+	if t, ok := s.(TypedSupporter); ok {
+		t.SetInt(name, value)
+		return
+	}
+	s.SetString(name, strconv.Itoa(value))
+}
+
+// GetInt64 is like GetInt but for int64.
+func GetInt64(s Section, name string) int64 {
+	if t, ok := s.(TypedSupporter); ok {
+		return t.GetInt64(name)
+	}
+	v, err := strconv.ParseInt(s.GetString(name), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// SetInt64 is like SetInt but for int64.
+func SetInt64(s Section, name string, value int64) {
+	if t, ok := s.(TypedSupporter); ok {
+		t.SetInt64(name, value)
+		return
+	}
+	s.SetString(name, strconv.FormatInt(value, 10))
+}
+
+// GetBool returns the value of name in s as a bool. It returns false if
+// the value is unset or unparseable.
+func GetBool(s Section, name string) bool {
+	if t, ok := s.(TypedSupporter); ok {
+		return t.GetBool(name)
+	}
+	v, err := strconv.ParseBool(s.GetString(name))
+	if err != nil {
+		return false
+	}
+	return v
+}
+
+// SetBool sets name in s to value.
+func SetBool(s Section, name string, value bool) {
+	if t, ok := s.(TypedSupporter); ok {
+		t.SetBool(name, value)
+		return
+	}
+	s.SetString(name, strconv.FormatBool(value))
+}
+
+// GetDuration returns the value of name in s as a time.Duration. It
+// returns 0 if the value is unset or unparseable.
+func GetDuration(s Section, name string) time.Duration {
+	if t, ok := s.(TypedSupporter); ok {
+		return t.GetDuration(name)
+	}
+	v, err := time.ParseDuration(s.GetString(name))
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// SetDuration sets name in s to value.
+func SetDuration(s Section, name string, value time.Duration) {
+	if t, ok := s.(TypedSupporter); ok {
+		t.SetDuration(name, value)
+		return
+	}
+	s.SetString(name, value.String())
+}
+
+// GetSizeSuffix returns the value of name in s as a fs.SizeSuffix. It
+// returns 0 if the value is unset or unparseable.
+func GetSizeSuffix(s Section, name string) fs.SizeSuffix {
+	if t, ok := s.(TypedSupporter); ok {
+		return t.GetSizeSuffix(name)
+	}
+	var v fs.SizeSuffix
+	if err := v.Set(s.GetString(name)); err != nil {
+		return 0
+	}
+	return v
+}
+
+// SetSizeSuffix sets name in s to value.
+func SetSizeSuffix(s Section, name string, value fs.SizeSuffix) {
+	if t, ok := s.(TypedSupporter); ok {
+		t.SetSizeSuffix(name, value)
+		return
+	}
+	s.SetString(name, value.String())
+}
+
+// GetStringList returns the value of name in s as a list of strings,
+// split on commas. It returns nil if the value is unset.
+func GetStringList(s Section, name string) []string {
+	if t, ok := s.(TypedSupporter); ok {
+		return t.GetStringList(name)
+	}
+	v := s.GetString(name)
+	if v == "" {
+		return nil
+	}
+	parts := strings.Split(v, ",")
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	return parts
+}
+
+// SetStringList sets name in s to value, joined with commas.
+func SetStringList(s Section, name string, value []string) {
+	if t, ok := s.(TypedSupporter); ok {
+		t.SetStringList(name, value)
+		return
+	}
+	s.SetString(name, strings.Join(value, ","))
+}
+
+// GetSecret returns the plaintext value of name in s - either an
+// obscured literal revealed with obscure.Reveal, or, if it's a
+// "keyring://name" reference, the value looked up in the active
+// SecretStore. It returns an error if the stored value can't be
+// resolved either way.
+func GetSecret(s Section, name string) (string, error) {
+	if t, ok := s.(TypedSupporter); ok {
+		return t.GetSecret(name)
+	}
+	return ResolveSecretValue(s.GetString(name))
+}
+
+// SetSecret obscures value with obscure.Obscure and stores the result
+// against name in s.
+func SetSecret(s Section, name string, value string) error {
+	if t, ok := s.(TypedSupporter); ok {
+		return t.SetSecret(name, value)
+	}
+	obscured, err := obscure.Obscure(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to obscure %q", name)
+	}
+	s.SetString(name, obscured)
+	return nil
+}
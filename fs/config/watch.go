@@ -0,0 +1,187 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+
+	"github.com/rclone/rclone/fs"
+)
+
+// configWatchDebounce coalesces the burst of filesystem events a single
+// save produces - editors commonly write a temp file and rename/swap it
+// into place, the same "<name>.old" pattern saveConfig itself uses -
+// into one reload.
+const configWatchDebounce = 500 * time.Millisecond
+
+// providerBox is a fixed concrete type to store in currentProvider:
+// atomic.Value requires every Store to use the same concrete type, which
+// a bare Provider interface value can't guarantee since different
+// providers are different concrete types.
+type providerBox struct {
+	provider Provider
+}
+
+// currentProvider is the provider GetRemoteConfig/GetConfigProvider read
+// from, published by LoadConfig, ConvertConfig and WatchConfig's reload
+// loop so concurrent readers never see a partially-loaded provider.
+var currentProvider atomic.Value // *providerBox
+
+// providerMu serializes the writers that build a new provider and
+// mutate the package-level `provider` var while doing so: LoadConfig,
+// ConvertConfig and reloadConfig. currentProvider's atomic.Value only
+// makes publishing safe for readers - without this lock, two of those
+// writers running concurrently (e.g. a WatchConfig reload racing an
+// rc-triggered ConvertConfig) could still interleave their writes to
+// `provider` and stomp each other's in-flight load.
+var providerMu sync.Mutex
+
+func publishProvider(p Provider) {
+	currentProvider.Store(&providerBox{provider: p})
+}
+
+// currentProviderValue returns the published provider, falling back to
+// the package-level provider var for callers racing ahead of the first
+// publishProvider (there shouldn't be any once LoadConfig has run).
+func currentProviderValue() Provider {
+	if v, ok := currentProvider.Load().(*providerBox); ok && v != nil {
+		return v.provider
+	}
+	return provider
+}
+
+var (
+	reloadSubsMu sync.Mutex
+	reloadSubs   []func(RemoteConfig)
+)
+
+// OnConfigReload registers fn to be called, with the freshly reloaded
+// RemoteConfig, whenever WatchConfig picks up a change to the config
+// file. Subsystems like the VFS or HTTP auth use it to invalidate
+// anything they've cached from the old config.
+func OnConfigReload(fn func(RemoteConfig)) {
+	reloadSubsMu.Lock()
+	defer reloadSubsMu.Unlock()
+	reloadSubs = append(reloadSubs, fn)
+}
+
+func notifyReload(rc RemoteConfig) {
+	reloadSubsMu.Lock()
+	subs := append([]func(RemoteConfig){}, reloadSubs...)
+	reloadSubsMu.Unlock()
+	for _, fn := range subs {
+		fn(rc)
+	}
+}
+
+// WatchConfig starts watching ConfigPath for changes and reloads it into
+// a fresh Provider whenever it settles after one. It watches the
+// containing directory rather than the file itself so edits that
+// replace the file (rename-and-swap, as saveConfig does) are seen.
+// The watch stops when ctx is cancelled.
+//
+// Reloading always rebuilds the provider from what's on disk, so an
+// in-memory change made with fs.ConfigFileSet/SaveConfig that hasn't
+// been flushed yet is discarded if a reload happens to land in between.
+// Callers that mix live edits with WatchConfig should call SaveConfig
+// before relying on the change surviving a reload.
+func WatchConfig(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "failed to create config file watcher")
+	}
+	dir := filepath.Dir(ConfigPath)
+	if err := watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+		return errors.Wrapf(err, "failed to watch %q", dir)
+	}
+	go watchConfigLoop(ctx, watcher)
+	return nil
+}
+
+func watchConfigLoop(ctx context.Context, watcher *fsnotify.Watcher) {
+	defer func() { _ = watcher.Close() }()
+
+	var debounce *time.Timer
+	reload := make(chan struct{}, 1)
+	defer func() {
+		if debounce != nil {
+			debounce.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if !configWatchEventRelevant(event) {
+				continue
+			}
+			if debounce == nil {
+				debounce = time.AfterFunc(configWatchDebounce, func() {
+					select {
+					case reload <- struct{}{}:
+					default:
+					}
+				})
+			} else {
+				debounce.Reset(configWatchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fs.Errorf(nil, "Config file watcher error: %v", err)
+		case <-reload:
+			reloadConfig()
+		}
+	}
+}
+
+// configWatchEventRelevant reports whether event touches ConfigPath
+// itself or a sibling sharing its name as a prefix, which covers the
+// temp file and ".old" backup a rename-and-swap save creates.
+func configWatchEventRelevant(event fsnotify.Event) bool {
+	base := filepath.Base(ConfigPath)
+	name := filepath.Base(event.Name)
+	return name == base || strings.HasPrefix(name, base)
+}
+
+// reloadConfig re-runs loadConfigFile into a fresh provider and
+// publishes it, leaving the previously published provider in place if
+// anything goes wrong.
+func reloadConfig() {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+
+	previous := provider
+
+	provider = providerForPath(ConfigPath)
+	if err := loadConfigFile(); err != nil && err != errorConfigFileNotFound {
+		fs.Errorf(nil, "Failed to reload config file %q: %v", ConfigPath, err)
+		provider = previous
+		return
+	}
+
+	layered, err := buildLayeredProvider(provider)
+	if err != nil {
+		fs.Errorf(nil, "Failed to rebuild layered config on reload: %v", err)
+		provider = previous
+		return
+	}
+	provider = layered
+
+	publishProvider(provider)
+	notifyReload(GetRemoteConfig())
+	fs.Logf(nil, "Reloaded config file %q", ConfigPath)
+}